@@ -1,83 +1,182 @@
-/*juiceconfig Abstract configurations between JSON file, Secrets Manager, and environment variables.
+/*juiceconfig Abstract configurations between JSON, YAML and dotenv files, Secrets Manager, and environment variables.
  */
 package juiceconfig
 
 import (
+	"crypto/sha256"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/antonholmquist/jason"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/fsnotify/fsnotify"
+	vaultapi "github.com/hashicorp/vault/api"
+	"gopkg.in/yaml.v3"
 )
 
 const (
-	FILE_PREFIX            = "file:::"
-	SECRETS_MANAGER_PREFIX = "secrets_manager:::"
-	ENVIRONMENT_PREFIX     = "environment:::"
+	FILE_PREFIX                 = "file:::"
+	SECRETS_MANAGER_PREFIX      = "secrets_manager:::"
+	ENVIRONMENT_PREFIX          = "environment:::"
+	YAML_FILE_PREFIX            = "yaml_file:::"
+	DOTENV_FILE_PREFIX          = "dotenv_file:::"
+	YAML_SECRETS_MANAGER_PREFIX = "yaml_secrets_manager:::"
+	VAULT_PREFIX                = "vault:::"
+)
+
+/*configFormat The format used to decode the raw bytes of a config source.
+ */
+type configFormat int
+
+const (
+	formatJSON configFormat = iota
+	formatYAML
+	formatDotenv
 )
 
 /*JuiceConfig Configuration object loading using a URL.
  */
 type JuiceConfig struct {
-	config   *map[string]*jason.Value
-	URL      string
-	hadError bool
-	errMsg   string
+	config      *map[string]*jason.Value
+	configMutex sync.RWMutex
+	URL         string
+	urls        []string
+	hadError    bool
+	errMsg      string
+
+	// Environment variable overrides (see AllowEnvOverrides)
+	envOverridesEnabled bool
+	envOverridesPrefix  string
+	envOverridesMutex   sync.Mutex
+	envOverrides        map[string]bool
 }
 
 /*Load Load configuration
+ *
+ *	One or more URLs may be given. They are loaded and flattened in order,
+ *	and merged into a single config, with values from later URLs overriding
+ *	values from earlier ones. This lets a deployment layer a baked-in
+ *	default file, a secrets bundle, and a per-environment patch, e.g:
+ *
+ *		Load("file:::base.json", "secrets_manager:::us-east-1:::prod", "environment:::OVERRIDES")
  */
-func Load(url string) (*JuiceConfig, error) {
-	// fmt.Printf("juiceconfig.Load(%s)\n", url)
+func Load(urls ...string) (*JuiceConfig, error) {
 	obj := &JuiceConfig{}
-	obj.URL = url
+	obj.URL = strings.Join(urls, ", ")
+	obj.urls = urls
+
+	if len(urls) == 0 {
+		return obj, obj.setError("No URL provided to JuiceConfig.Load")
+	}
+
+	// Load and flatten each source, merging them in order.
+	newConfig := map[string]*jason.Value{}
+	for _, url := range urls {
+		source, err := loadSource(url)
+		if err != nil {
+			return obj, obj.setError(err.Error())
+		}
+		for path, value := range source {
+			newConfig[path] = value
+		}
+	}
+	obj.config = &newConfig
+
+	// All good
+	obj.hadError = false
+	return obj, nil
+}
+
+/*loadSource Load and flatten the configuration found at a single URL.
+ */
+func loadSource(url string) (map[string]*jason.Value, error) {
+	data, format, err := fetchRaw(url)
+	if err != nil {
+		return nil, err
+	}
+	return decodeConfig(format, data)
+}
+
+/*fetchRaw Fetch the raw bytes for a single URL, along with the format they should
+ *be decoded with. Used both by loadSource and by Watch, which needs the raw
+ *bytes to detect changes without necessarily reparsing them.
+ */
+func fetchRaw(url string) ([]byte, configFormat, error) {
+	// fmt.Printf("juiceconfig.fetchRaw(%s)\n", url)
 
 	var data []byte
 	var err error
-	if strings.HasPrefix(url, FILE_PREFIX) {
+	format := formatJSON
+
+	if strings.HasPrefix(url, YAML_FILE_PREFIX) {
+
+		// Load YAML configuration from a file
+		filename := url[len(YAML_FILE_PREFIX):]
+		format = formatYAML
+		data, err = ioutil.ReadFile(filename)
+		if err != nil {
+			fmt.Printf("Unable to open config file %s\n", filename)
+			return nil, format, errors.New("Unable to open config file [" + filename + "]")
+		}
+
+	} else if strings.HasPrefix(url, DOTENV_FILE_PREFIX) {
+
+		// Load dotenv configuration from a file
+		filename := url[len(DOTENV_FILE_PREFIX):]
+		format = formatDotenv
+		data, err = ioutil.ReadFile(filename)
+		if err != nil {
+			fmt.Printf("Unable to open config file %s\n", filename)
+			return nil, format, errors.New("Unable to open config file [" + filename + "]")
+		}
+
+	} else if strings.HasPrefix(url, YAML_SECRETS_MANAGER_PREFIX) {
+
+		// Get a YAML secret from AWS Secrets Manager
+		format = formatYAML
+		data, err = readSecretsManagerValue(url, YAML_SECRETS_MANAGER_PREFIX)
+		if err != nil {
+			return nil, format, err
+		}
+
+	} else if strings.HasPrefix(url, FILE_PREFIX) {
 
 		// Load configuration from a file
 		filename := url[len(FILE_PREFIX):]
 		data, err = ioutil.ReadFile(filename)
 		if err != nil {
 			fmt.Printf("Unable to open config file %s\n", filename)
-			return obj, obj.setError("Unable to open config file [" + filename + "]")
+			return nil, format, errors.New("Unable to open config file [" + filename + "]")
 		}
+		format = sniffFormat(filename)
 
 	} else if strings.HasPrefix(url, SECRETS_MANAGER_PREFIX) {
 
 		// Get the configuration from AWS Secrets Manager
-		def := url[len(SECRETS_MANAGER_PREFIX):]
-		// Split into region:::secretName
-		pos := strings.Index(def, ":::")
-		if pos < 0 {
-			fmt.Printf("Invalid URL for JuiceConfig: %s\n", url)
-			return obj, obj.setError("Invalid URL [" + url + "]")
-		}
-		region := def[0:pos]
-		secretName := def[pos+3:]
-		// Connect to AWS
-		sess := session.Must(session.NewSession(&aws.Config{
-			Region: &region,
-		}))
-		svc := secretsmanager.New(sess)
-		params := &secretsmanager.GetSecretValueInput{
-			SecretId:     aws.String(secretName),
-			VersionStage: aws.String("AWSCURRENT"),
-		}
-		// Get the secret
-		result, err := svc.GetSecretValue(params)
+		data, err = readSecretsManagerValue(url, SECRETS_MANAGER_PREFIX)
+		if err != nil {
+			return nil, format, err
+		}
+
+	} else if strings.HasPrefix(url, VAULT_PREFIX) {
+
+		// Get the configuration from HashiCorp Vault
+		data, err = readVaultValue(url)
 		if err != nil {
-			fmt.Printf("Unable to access AWS Secrets Manager: %+v\n", err)
-			return obj, obj.setError("Unable to access AWS Secrets Manager [" + err.Error() + "]")
+			return nil, format, err
 		}
-		secret := result.SecretString
-		data = []byte(*secret)
 
 	} else if strings.HasPrefix(url, ENVIRONMENT_PREFIX) {
 
@@ -86,7 +185,7 @@ func Load(url string) (*JuiceConfig, error) {
 		envvar := os.Getenv(variableName)
 		if envvar == "" {
 			fmt.Println("Environment variable not set [" + variableName + "]")
-			return obj, obj.setError("Environment variable not set [" + variableName + "]")
+			return nil, format, errors.New("Environment variable not set [" + variableName + "]")
 		}
 		data = []byte(envvar)
 
@@ -94,14 +193,183 @@ func Load(url string) (*JuiceConfig, error) {
 
 		// Unknown URL prefix.
 		fmt.Printf("Invalid URL for JuiceConfig: %s\n", url)
-		return obj, obj.setError("Invalid URL for JuiceConfig [" + url + "]")
+		return nil, format, errors.New("Invalid URL for JuiceConfig [" + url + "]")
 	}
 
-	// Parse the configuration
+	return data, format, nil
+}
+
+/*filenameForURL If a URL refers to a local file (file:::, yaml_file:::, dotenv_file:::),
+ *return its filename.
+ */
+func filenameForURL(url string) (string, bool) {
+	for _, prefix := range []string{FILE_PREFIX, YAML_FILE_PREFIX, DOTENV_FILE_PREFIX} {
+		if strings.HasPrefix(url, prefix) {
+			return url[len(prefix):], true
+		}
+	}
+	return "", false
+}
+
+/*readSecretsManagerValue Fetch the raw secret string for a "<prefix><region>:::<secretName>" URL.
+ */
+func readSecretsManagerValue(url string, prefix string) ([]byte, error) {
+	def := url[len(prefix):]
+	// Split into region:::secretName
+	pos := strings.Index(def, ":::")
+	if pos < 0 {
+		fmt.Printf("Invalid URL for JuiceConfig: %s\n", url)
+		return nil, errors.New("Invalid URL [" + url + "]")
+	}
+	region := def[0:pos]
+	secretName := def[pos+3:]
+	// Connect to AWS
+	sess := session.Must(session.NewSession(&aws.Config{
+		Region: &region,
+	}))
+	svc := secretsmanager.New(sess)
+	params := &secretsmanager.GetSecretValueInput{
+		SecretId:     aws.String(secretName),
+		VersionStage: aws.String("AWSCURRENT"),
+	}
+	// Get the secret
+	result, err := svc.GetSecretValue(params)
+	if err != nil {
+		fmt.Printf("Unable to access AWS Secrets Manager: %+v\n", err)
+		return nil, errors.New("Unable to access AWS Secrets Manager [" + err.Error() + "]")
+	}
+	return []byte(*result.SecretString), nil
+}
+
+/*readVaultValue Fetch a KV v2 secret's data from a "vault:::<addr>:::<path>" URL, as
+ *flat JSON bytes ready for decodeConfig.
+ */
+func readVaultValue(url string) ([]byte, error) {
+	def := url[len(VAULT_PREFIX):]
+	// Split into addr:::path
+	pos := strings.Index(def, ":::")
+	if pos < 0 {
+		fmt.Printf("Invalid URL for JuiceConfig: %s\n", url)
+		return nil, errors.New("Invalid URL [" + url + "]")
+	}
+	addr := def[0:pos]
+	path := def[pos+3:]
+
+	config := vaultapi.DefaultConfig()
+	config.Address = addr
+	client, err := vaultapi.NewClient(config)
+	if err != nil {
+		return nil, errors.New("Unable to create Vault client [" + err.Error() + "]")
+	}
+	if err := vaultAuthenticate(client); err != nil {
+		return nil, err
+	}
+
+	secret, err := client.Logical().Read(path)
+	if err != nil {
+		fmt.Printf("Unable to read Vault secret: %+v\n", err)
+		return nil, errors.New("Unable to read Vault secret [" + err.Error() + "]")
+	}
+	if secret == nil || secret.Data == nil {
+		return nil, errors.New("Vault secret not found [" + path + "]")
+	}
+
+	// KV v2 secrets nest the actual values under "data"
+	values, ok := secret.Data["data"].(map[string]interface{})
+	if !ok {
+		return nil, errors.New("Vault secret at [" + path + "] is not a KV v2 secret")
+	}
+	jsonData, err := json.Marshal(values)
+	if err != nil {
+		return nil, errors.New("Error converting Vault secret to JSON [" + err.Error() + "]")
+	}
+	return jsonData, nil
+}
+
+/*vaultAuthenticate Authenticate a Vault client, preferring a VAULT_TOKEN and falling back
+ *to AppRole login via VAULT_ROLE_ID/VAULT_SECRET_ID.
+ */
+func vaultAuthenticate(client *vaultapi.Client) error {
+	if token := os.Getenv("VAULT_TOKEN"); token != "" {
+		client.SetToken(token)
+		return nil
+	}
+
+	roleID := os.Getenv("VAULT_ROLE_ID")
+	secretID := os.Getenv("VAULT_SECRET_ID")
+	if roleID == "" || secretID == "" {
+		return errors.New("No Vault credentials found (set VAULT_TOKEN or VAULT_ROLE_ID/VAULT_SECRET_ID)")
+	}
+
+	secret, err := client.Logical().Write("auth/approle/login", map[string]interface{}{
+		"role_id":   roleID,
+		"secret_id": secretID,
+	})
+	if err != nil {
+		return errors.New("Unable to authenticate to Vault via AppRole [" + err.Error() + "]")
+	}
+	if secret == nil || secret.Auth == nil {
+		return errors.New("Vault AppRole login returned no auth")
+	}
+	client.SetToken(secret.Auth.ClientToken)
+	return nil
+}
+
+/*sniffFormat Guess the config format of a file::: source from its filename
+ *extension, defaulting to JSON. secrets_manager::: sources are always decoded as JSON.
+ */
+func sniffFormat(filename string) configFormat {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".yaml", ".yml":
+		return formatYAML
+	case ".env":
+		return formatDotenv
+	default:
+		return formatJSON
+	}
+}
+
+/*decodeConfig Decode raw config bytes of the given format into the internal
+ *flattened map[string]*jason.Value representation.
+ */
+func decodeConfig(format configFormat, data []byte) (map[string]*jason.Value, error) {
+	switch format {
+	case formatYAML:
+		jsonData, err := yamlToJSON(data)
+		if err != nil {
+			return nil, err
+		}
+		return decodeJSON(jsonData)
+	case formatDotenv:
+		return decodeDotenv(data)
+	default:
+		return decodeJSON(data)
+	}
+}
+
+/*yamlToJSON Convert YAML bytes to JSON bytes, so they can be parsed by jason and
+ *flattened like any other config source.
+ */
+func yamlToJSON(data []byte) ([]byte, error) {
+	var generic map[string]interface{}
+	if err := yaml.Unmarshal(data, &generic); err != nil {
+		fmt.Printf("Error parsing YAML config: %+v\n", err)
+		return nil, errors.New("Error parsing YAML config [" + err.Error() + "]")
+	}
+	jsonData, err := json.Marshal(generic)
+	if err != nil {
+		return nil, errors.New("Error converting YAML config to JSON [" + err.Error() + "]")
+	}
+	return jsonData, nil
+}
+
+/*decodeJSON Parse and flatten JSON config bytes.
+ */
+func decodeJSON(data []byte) (map[string]*jason.Value, error) {
 	config, err := jason.NewObjectFromBytes(data)
 	if err != nil {
 		fmt.Printf("Error parsing config file: %+v\n", err)
-		return obj, obj.setError("Error parsing config file [" + err.Error() + "]")
+		return nil, errors.New("Error parsing config file [" + err.Error() + "]")
 	}
 
 	// Flatten the config
@@ -109,13 +377,38 @@ func Load(url string) (*JuiceConfig, error) {
 	err = flattenConfig(&newConfig, "", config)
 	if err != nil {
 		fmt.Printf("Error flattening config: ", err)
-		return obj, obj.setError("Error flattening config [" + err.Error() + "]")
+		return nil, errors.New("Error flattening config [" + err.Error() + "]")
 	}
-	obj.config = &newConfig
+	return newConfig, nil
+}
 
-	// All good
-	obj.hadError = false
-	return obj, nil
+/*decodeDotenv Parse "KEY=VALUE" dotenv lines into a flat config.
+ *
+ *	Dotenv files have no nesting, so the keys are used as-is as paths
+ *	(e.g. "APP_NAME=myApp" becomes the path "APP_NAME").
+ */
+func decodeDotenv(data []byte) (map[string]*jason.Value, error) {
+	flat := map[string]string{}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		pos := strings.Index(line, "=")
+		if pos < 0 {
+			continue
+		}
+		key := strings.TrimSpace(line[0:pos])
+		value := strings.TrimSpace(line[pos+1:])
+		value = strings.Trim(value, `"'`)
+		flat[key] = value
+	}
+
+	jsonData, err := json.Marshal(flat)
+	if err != nil {
+		return nil, errors.New("Error converting dotenv config to JSON [" + err.Error() + "]")
+	}
+	return decodeJSON(jsonData)
 }
 
 /*flattenConfig Recursively flatten a configuration.
@@ -179,118 +472,941 @@ func (jc *JuiceConfig) setError(msg string) error {
 	return errors.New(msg)
 }
 
-/*GetString Get a string configuration value
+/*AllowEnvOverrides Opt in to letting environment variables override config values.
+ *
+ *	Once enabled, any flattened path (e.g. "app.name") can be overridden by
+ *	setting an environment variable named after the path, upper-cased with
+ *	dots replaced by underscores and prefixed with prefix + "_"
+ *	(e.g. "PREFIX_APP_NAME"). This is checked by GetString/GetInt/GetBool
+ *	ahead of the loaded config, so ops can patch individual settings at
+ *	runtime without redeploying a config file.
  */
-func (jc *JuiceConfig) GetString(path string, dflt ...string) (string, error) {
-	if jc.hadError {
-		return "", errors.New("Already had error")
+func (jc *JuiceConfig) AllowEnvOverrides(prefix string) {
+	jc.envOverridesEnabled = true
+	jc.envOverridesPrefix = prefix
+	jc.envOverrides = map[string]bool{}
+}
+
+/*GetEnvironmentOverrides Report which paths were sourced from the environment.
+ *
+ *	Only paths that have actually been read via one of the getters
+ *	(GetString, GetInt, GetBool, GetFloat64, GetDuration, GetStringSlice,
+ *	or the same on a Session) are recorded - this isn't a scan of every possible env var, just a log
+ *	of overrides that have taken effect so far.
+ */
+func (jc *JuiceConfig) GetEnvironmentOverrides() map[string]bool {
+	jc.envOverridesMutex.Lock()
+	defer jc.envOverridesMutex.Unlock()
+	overrides := map[string]bool{}
+	for path := range jc.envOverrides {
+		overrides[path] = true
+	}
+	return overrides
+}
+
+/*envOverrideFor Check whether an environment variable overrides the given path.
+ */
+func (jc *JuiceConfig) envOverrideFor(path string) (string, bool) {
+	if !jc.envOverridesEnabled {
+		return "", false
+	}
+	envName := jc.envOverridesPrefix + "_" + strings.ToUpper(strings.ReplaceAll(path, ".", "_"))
+	value, ok := os.LookupEnv(envName)
+	if ok {
+		jc.envOverridesMutex.Lock()
+		jc.envOverrides[path] = true
+		jc.envOverridesMutex.Unlock()
+	}
+	return value, ok
+}
+
+/*Watch Hot-reload the config in the background and invoke onChange whenever it
+ *changes.
+ *
+ *	jc must have been created via Load. file:::, yaml_file::: and
+ *	dotenv_file::: sources are watched with fsnotify; all other sources
+ *	(secrets_manager:::, vault:::, environment:::) are polled every
+ *	interval, comparing a hash of their raw bytes so unchanged sources
+ *	aren't reparsed. On any change, all sources are reloaded and merged
+ *	exactly as Load does, the new config is swapped in under configMutex,
+ *	and onChange is called with the changed paths.
+ *
+ *	Per fsnotify's own caveat, watching a file's literal path misses the
+ *	atomic write-then-rename pattern used by most safe config writers (and
+ *	by ConfigMap symlink swaps), since the watched inode is never touched.
+ *	We instead watch each file's parent directory and filter events down
+ *	to that file's name, reloading on Write, Create or Rename of it. Events
+ *	on other entries in the directory are ignored, so a ConfigMap-style swap
+ *	of a "..data" symlink that the file points through does not trigger a
+ *	reload.
+ *
+ *	The returned stop function stops watching and must be called to avoid
+ *	leaking the background goroutine.
+ */
+func (jc *JuiceConfig) Watch(interval time.Duration, onChange func(jc *JuiceConfig, changedPaths []string)) (func(), error) {
+	if len(jc.urls) == 0 {
+		return nil, errors.New("Watch requires a JuiceConfig created via Load")
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, errors.New("Unable to start config file watcher [" + err.Error() + "]")
+	}
+
+	var pollURLs []string
+	hashes := map[string][32]byte{}
+	watchedFiles := map[string]bool{}
+	watchedDirs := map[string]bool{}
+	for _, url := range jc.urls {
+		if filename, ok := filenameForURL(url); ok {
+			abs, err := filepath.Abs(filename)
+			if err != nil {
+				abs = filename
+			}
+			watchedFiles[abs] = true
+			dir := filepath.Dir(abs)
+			if !watchedDirs[dir] {
+				if err := watcher.Add(dir); err != nil {
+					fmt.Printf("Unable to watch config directory %s: %+v\n", dir, err)
+				}
+				watchedDirs[dir] = true
+			}
+			continue
+		}
+		pollURLs = append(pollURLs, url)
+		if data, _, err := fetchRaw(url); err == nil {
+			hashes[url] = sha256.Sum256(data)
+		}
+	}
+
+	reload := func() {
+		newObj, err := Load(jc.urls...)
+		if err != nil {
+			fmt.Printf("Error reloading config: %+v\n", err)
+			return
+		}
+
+		jc.configMutex.Lock()
+		oldConfig := jc.config
+		jc.config = newObj.config
+		jc.configMutex.Unlock()
+
+		if onChange != nil {
+			changedPaths := diffConfig(oldConfig, newObj.config)
+			if len(changedPaths) > 0 {
+				onChange(jc, changedPaths)
+			}
+		}
+	}
+
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+
+	go func() {
+		defer ticker.Stop()
+		defer watcher.Close()
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				abs, err := filepath.Abs(event.Name)
+				if err != nil {
+					abs = event.Name
+				}
+				if !watchedFiles[abs] {
+					continue
+				}
+				if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) != 0 {
+					reload()
+				}
+			case <-watcher.Errors:
+				// Keep watching - a transient watch error shouldn't stop hot-reload.
+			case <-ticker.C:
+				changed := false
+				for _, url := range pollURLs {
+					data, _, err := fetchRaw(url)
+					if err != nil {
+						continue
+					}
+					sum := sha256.Sum256(data)
+					if sum != hashes[url] {
+						hashes[url] = sum
+						changed = true
+					}
+				}
+				if changed {
+					reload()
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	stop := func() {
+		close(done)
+	}
+	return stop, nil
+}
+
+/*diffConfig Return the sorted list of paths that were added, removed or changed
+ *between two flattened configs.
+ */
+func diffConfig(oldConfig *map[string]*jason.Value, newConfig *map[string]*jason.Value) []string {
+	changed := map[string]bool{}
+	for path, value := range *newConfig {
+		oldValue, ok := (*oldConfig)[path]
+		if !ok || !sameValue(oldValue, value) {
+			changed[path] = true
+		}
+	}
+	for path := range *oldConfig {
+		if _, ok := (*newConfig)[path]; !ok {
+			changed[path] = true
+		}
+	}
+
+	paths := make([]string, 0, len(changed))
+	for path := range changed {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+/*sameValue Compare two config values for equality via their JSON representation.
+ *	jason.Value only exposes its decoded data through Marshal(), so we
+ *	compare that rather than json.Marshal(value) directly, which would
+ *	just marshal the value's unexported fields (always "{}").
+ */
+func sameValue(a *jason.Value, b *jason.Value) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	aBytes, aErr := a.Marshal()
+	bBytes, bErr := b.Marshal()
+	if aErr != nil || bErr != nil {
+		return false
 	}
+	return string(aBytes) == string(bBytes)
+}
+
+/*getValue Look up a path in the current config, guarded against a concurrent
+ *reload from Watch.
+ */
+func (jc *JuiceConfig) getValue(path string) (*jason.Value, bool) {
+	jc.configMutex.RLock()
+	defer jc.configMutex.RUnlock()
 	jvalue, ok := (*jc.config)[path]
+	return jvalue, ok
+}
+
+/*lookupString Look up a string value without touching jc's sticky error state.
+ *	Shared by GetString and Session.GetString.
+ */
+func (jc *JuiceConfig) lookupString(path string, dflt ...string) (string, error) {
+	if value, ok := jc.envOverrideFor(path); ok {
+		return value, nil
+	}
+	jvalue, ok := jc.getValue(path)
 	if ok {
 		value, err := jvalue.String()
 		if err == nil {
 			return value, nil
 		}
-		return "", jc.setError("Value is not string [" + path + "]")
+		return "", errors.New("Value is not string [" + path + "]")
 	}
 
 	// Value not found. Is there a default?
 	if len(dflt) > 0 {
 		return dflt[0], nil
 	}
-	return "", jc.setError("Value not found [" + path + "]")
+	return "", errors.New("Value not found [" + path + "]")
 }
 
-/*GetInt Get an integer configuration value
+/*lookupInt Look up an int64 value without touching jc's sticky error state.
+ *	Shared by GetInt and Session.GetInt.
  */
-func (jc *JuiceConfig) GetInt(path string, dflt ...int64) (int64, error) {
-	if jc.hadError {
-		return 0, errors.New("Already had error")
+func (jc *JuiceConfig) lookupInt(path string, dflt ...int64) (int64, error) {
+	if raw, ok := jc.envOverrideFor(path); ok {
+		value, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return 0, errors.New("Environment override is not int64 [" + path + "]")
+		}
+		return value, nil
 	}
-	jvalue, ok := (*jc.config)[path]
+	jvalue, ok := jc.getValue(path)
 	if ok {
 		value, err := jvalue.Int64()
 		if err == nil {
 			return value, nil
 		}
-		return 0, jc.setError("Value is not int64 [" + path + "]")
+		return 0, errors.New("Value is not int64 [" + path + "]")
 	}
 
 	// Value not found. Is there a default?
 	if len(dflt) > 0 {
 		return dflt[0], nil
 	}
-	return 0, jc.setError("Value not found [" + path + "]")
+	return 0, errors.New("Value not found [" + path + "]")
 }
 
-/*GetBool Get an integer configuration value
+/*lookupBool Look up a bool value without touching jc's sticky error state.
+ *	Shared by GetBool and Session.GetBool.
  */
-func (jc *JuiceConfig) GetBool(path string, dflt ...bool) (bool, error) {
-	if jc.hadError {
-		return false, errors.New("Already had error")
+func (jc *JuiceConfig) lookupBool(path string, dflt ...bool) (bool, error) {
+	if raw, ok := jc.envOverrideFor(path); ok {
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			return false, errors.New("Environment override is not bool [" + path + "]")
+		}
+		return value, nil
 	}
-	jvalue, ok := (*jc.config)[path]
+	jvalue, ok := jc.getValue(path)
 	if ok {
 		value, err := jvalue.Boolean()
 		if err == nil {
 			return value, nil
 		}
-		return false, jc.setError("Value is not bool [" + path + "]")
+		return false, errors.New("Value is not bool [" + path + "]")
+	}
+
+	// Value not found. Is there a default?
+	if len(dflt) > 0 {
+		return dflt[0], nil
+	}
+	return false, errors.New("Value not found [" + path + "]")
+}
+
+/*lookupFloat64 Look up a float64 value without touching jc's sticky error state.
+ *	Shared by GetFloat64 and Session.GetFloat64.
+ */
+func (jc *JuiceConfig) lookupFloat64(path string, dflt ...float64) (float64, error) {
+	if raw, ok := jc.envOverrideFor(path); ok {
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return 0, errors.New("Environment override is not float64 [" + path + "]")
+		}
+		return value, nil
+	}
+	jvalue, ok := jc.getValue(path)
+	if ok {
+		value, err := jvalue.Float64()
+		if err == nil {
+			return value, nil
+		}
+		return 0, errors.New("Value is not float64 [" + path + "]")
+	}
+
+	// Value not found. Is there a default?
+	if len(dflt) > 0 {
+		return dflt[0], nil
+	}
+	return 0, errors.New("Value not found [" + path + "]")
+}
+
+/*lookupDuration Look up a time.Duration value without touching jc's sticky error state.
+ *	Accepts anything time.ParseDuration understands (e.g. "30s"), or a plain
+ *	number of seconds, either as a JSON number or a numeric string.
+ *	Shared by GetDuration and Session.GetDuration.
+ */
+func (jc *JuiceConfig) lookupDuration(path string, dflt ...time.Duration) (time.Duration, error) {
+	if raw, ok := jc.envOverrideFor(path); ok {
+		value, err := parseDuration(raw)
+		if err != nil {
+			return 0, errors.New("Environment override is not a duration [" + path + "]")
+		}
+		return value, nil
+	}
+	jvalue, ok := jc.getValue(path)
+	if ok {
+		if raw, err := jvalue.String(); err == nil {
+			value, err := parseDuration(raw)
+			if err != nil {
+				return 0, errors.New("Value is not a duration [" + path + "]")
+			}
+			return value, nil
+		}
+		if seconds, err := jvalue.Int64(); err == nil {
+			return time.Duration(seconds) * time.Second, nil
+		}
+		return 0, errors.New("Value is not a duration [" + path + "]")
+	}
+
+	// Value not found. Is there a default?
+	if len(dflt) > 0 {
+		return dflt[0], nil
+	}
+	return 0, errors.New("Value not found [" + path + "]")
+}
+
+/*parseDuration Parse a duration string, accepting both time.ParseDuration syntax
+ *("30s") and a plain integer number of seconds ("30").
+ */
+func parseDuration(raw string) (time.Duration, error) {
+	if value, err := time.ParseDuration(raw); err == nil {
+		return value, nil
+	}
+	if seconds, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		return time.Duration(seconds) * time.Second, nil
+	}
+	return 0, errors.New("Not a valid duration [" + raw + "]")
+}
+
+/*lookupStringSlice Look up a string slice without touching jc's sticky error state.
+ *	Reads a flattened JSON array, or splits a single string value on commas.
+ *	Shared by GetStringSlice and Session.GetStringSlice.
+ */
+func (jc *JuiceConfig) lookupStringSlice(path string, dflt ...[]string) ([]string, error) {
+	if raw, ok := jc.envOverrideFor(path); ok {
+		return splitCommaList(raw), nil
+	}
+	jvalue, ok := jc.getValue(path)
+	if ok {
+		if items, err := jvalue.Array(); err == nil {
+			values := make([]string, 0, len(items))
+			for _, item := range items {
+				value, err := item.String()
+				if err != nil {
+					return nil, errors.New("Value is not a string array [" + path + "]")
+				}
+				values = append(values, value)
+			}
+			return values, nil
+		}
+		if raw, err := jvalue.String(); err == nil {
+			return splitCommaList(raw), nil
+		}
+		return nil, errors.New("Value is not a string slice [" + path + "]")
 	}
 
 	// Value not found. Is there a default?
 	if len(dflt) > 0 {
 		return dflt[0], nil
 	}
-	return false, jc.setError("Value not found [" + path + "]")
+	return nil, errors.New("Value not found [" + path + "]")
+}
+
+/*splitCommaList Split a comma-separated string into a trimmed, non-empty string slice.
+ */
+func splitCommaList(raw string) []string {
+	parts := strings.Split(raw, ",")
+	values := make([]string, 0, len(parts))
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part != "" {
+			values = append(values, part)
+		}
+	}
+	return values
+}
+
+/*GetString Get a string configuration value
+ */
+func (jc *JuiceConfig) GetString(path string, dflt ...string) (string, error) {
+	if jc.hadError {
+		return "", errors.New("Already had error")
+	}
+	value, err := jc.lookupString(path, dflt...)
+	if err != nil {
+		return "", jc.setError(err.Error())
+	}
+	return value, nil
+}
+
+/*GetInt Get an integer configuration value
+ */
+func (jc *JuiceConfig) GetInt(path string, dflt ...int64) (int64, error) {
+	if jc.hadError {
+		return 0, errors.New("Already had error")
+	}
+	value, err := jc.lookupInt(path, dflt...)
+	if err != nil {
+		return 0, jc.setError(err.Error())
+	}
+	return value, nil
+}
+
+/*GetBool Get an integer configuration value
+ */
+func (jc *JuiceConfig) GetBool(path string, dflt ...bool) (bool, error) {
+	if jc.hadError {
+		return false, errors.New("Already had error")
+	}
+	value, err := jc.lookupBool(path, dflt...)
+	if err != nil {
+		return false, jc.setError(err.Error())
+	}
+	return value, nil
+}
+
+/*GetFloat64 Get a float64 configuration value
+ */
+func (jc *JuiceConfig) GetFloat64(path string, dflt ...float64) (float64, error) {
+	if jc.hadError {
+		return 0, errors.New("Already had error")
+	}
+	value, err := jc.lookupFloat64(path, dflt...)
+	if err != nil {
+		return 0, jc.setError(err.Error())
+	}
+	return value, nil
+}
+
+/*GetDuration Get a time.Duration configuration value
+ *
+ *	Accepts anything time.ParseDuration understands (e.g. "30s", "5m"), or a
+ *	plain number of seconds, either as a JSON number or a numeric string.
+ */
+func (jc *JuiceConfig) GetDuration(path string, dflt ...time.Duration) (time.Duration, error) {
+	if jc.hadError {
+		return 0, errors.New("Already had error")
+	}
+	value, err := jc.lookupDuration(path, dflt...)
+	if err != nil {
+		return 0, jc.setError(err.Error())
+	}
+	return value, nil
+}
+
+/*GetStringSlice Get a string slice configuration value
+ *
+ *	Reads a flattened JSON array (e.g. ["a", "b"]), or splits a single string
+ *	value on commas (e.g. "a,b" or, as an environment override, "a, b").
+ */
+func (jc *JuiceConfig) GetStringSlice(path string, dflt ...[]string) ([]string, error) {
+	if jc.hadError {
+		return nil, errors.New("Already had error")
+	}
+	value, err := jc.lookupStringSlice(path, dflt...)
+	if err != nil {
+		return nil, jc.setError(err.Error())
+	}
+	return value, nil
+}
+
+/*GetSubConfig Return a new JuiceConfig scoped to the keys under "prefix.", with that
+ *prefix stripped, so library code can be handed a slice of the tree without
+ *knowing the top-level layout.
+ *
+ *	The returned JuiceConfig shares no mutable state with jc - it has its own
+ *	sticky error and is not kept in sync with later Watch reloads of jc.
+ */
+func (jc *JuiceConfig) GetSubConfig(prefix string) *JuiceConfig {
+	if !strings.HasSuffix(prefix, ".") {
+		prefix = prefix + "."
+	}
+
+	jc.configMutex.RLock()
+	subConfig := map[string]*jason.Value{}
+	for path, value := range *jc.config {
+		if strings.HasPrefix(path, prefix) {
+			subConfig[strings.TrimPrefix(path, prefix)] = value
+		}
+	}
+	jc.configMutex.RUnlock()
+
+	return &JuiceConfig{
+		config: &subConfig,
+		URL:    jc.URL + " [" + prefix + "]",
+	}
+}
+
+/*Session Return a handle sharing this config's values but owning its own sticky
+ *error, independent of jc and of any other Session. Use this when multiple
+ *goroutines need the "check WasError() at the end" pattern concurrently
+ *against the same JuiceConfig without poisoning each other's error state.
+ */
+func (jc *JuiceConfig) Session() *Session {
+	return &Session{jc: jc}
+}
+
+/*Session A sticky-error handle onto a JuiceConfig, safe to use independently of
+ *other Sessions or of the underlying JuiceConfig's own GetString/GetInt/GetBool.
+ *Its own error state is guarded by errMutex so a single Session can itself be
+ *shared and called concurrently (e.g. the package-level defaultSession).
+ */
+type Session struct {
+	jc       *JuiceConfig
+	errMutex sync.Mutex
+	hadError bool
+	errMsg   string
+}
+
+/*GetString Get a string configuration value, remembering errors on this Session only.
+ */
+func (s *Session) GetString(path string, dflt ...string) (string, error) {
+	if s.WasError() {
+		return "", errors.New("Already had error")
+	}
+	value, err := s.jc.lookupString(path, dflt...)
+	if err != nil {
+		return "", s.setError(err.Error())
+	}
+	return value, nil
+}
+
+/*GetInt Get an integer configuration value, remembering errors on this Session only.
+ */
+func (s *Session) GetInt(path string, dflt ...int64) (int64, error) {
+	if s.WasError() {
+		return 0, errors.New("Already had error")
+	}
+	value, err := s.jc.lookupInt(path, dflt...)
+	if err != nil {
+		return 0, s.setError(err.Error())
+	}
+	return value, nil
+}
+
+/*GetBool Get a boolean configuration value, remembering errors on this Session only.
+ */
+func (s *Session) GetBool(path string, dflt ...bool) (bool, error) {
+	if s.WasError() {
+		return false, errors.New("Already had error")
+	}
+	value, err := s.jc.lookupBool(path, dflt...)
+	if err != nil {
+		return false, s.setError(err.Error())
+	}
+	return value, nil
+}
+
+/*GetFloat64 Get a float64 configuration value, remembering errors on this Session only.
+ */
+func (s *Session) GetFloat64(path string, dflt ...float64) (float64, error) {
+	if s.WasError() {
+		return 0, errors.New("Already had error")
+	}
+	value, err := s.jc.lookupFloat64(path, dflt...)
+	if err != nil {
+		return 0, s.setError(err.Error())
+	}
+	return value, nil
+}
+
+/*GetDuration Get a time.Duration configuration value, remembering errors on this Session only.
+ */
+func (s *Session) GetDuration(path string, dflt ...time.Duration) (time.Duration, error) {
+	if s.WasError() {
+		return 0, errors.New("Already had error")
+	}
+	value, err := s.jc.lookupDuration(path, dflt...)
+	if err != nil {
+		return 0, s.setError(err.Error())
+	}
+	return value, nil
+}
+
+/*GetStringSlice Get a string slice configuration value, remembering errors on this Session only.
+ */
+func (s *Session) GetStringSlice(path string, dflt ...[]string) ([]string, error) {
+	if s.WasError() {
+		return nil, errors.New("Already had error")
+	}
+	value, err := s.jc.lookupStringSlice(path, dflt...)
+	if err != nil {
+		return nil, s.setError(err.Error())
+	}
+	return value, nil
+}
+
+/*setError Set the Session's own error status, remember the message, and return an
+ *Error object.
+ */
+func (s *Session) setError(msg string) error {
+	s.errMutex.Lock()
+	defer s.errMutex.Unlock()
+	s.hadError = true
+	s.errMsg = msg
+	return errors.New(msg)
+}
+
+/*WasError Has an error occurred on this Session?
+ */
+func (s *Session) WasError() bool {
+	s.errMutex.Lock()
+	defer s.errMutex.Unlock()
+	return s.hadError
+}
+
+/*ErrorMessage Return description of this Session's previous error.
+ */
+func (s *Session) ErrorMessage() string {
+	s.errMutex.Lock()
+	defer s.errMutex.Unlock()
+	return s.errMsg
+}
+
+/*ResetError Reset this Session's error state.
+ */
+func (s *Session) ResetError() {
+	s.errMutex.Lock()
+	defer s.errMutex.Unlock()
+	s.hadError = false
+}
+
+/*Unmarshal Populate a tagged struct from the config, aggregating every problem found.
+ *
+ *	Fields are matched using a `juice:"path[,required][,default=value]"` tag,
+ *	e.g:
+ *
+ *		type AppConfig struct {
+ *			Name string `juice:"app.name,required"`
+ *			Size int64  `juice:"app.size,default=10"`
+ *		}
+ *
+ *	Unlike GetString/GetInt/GetBool, which stop at the first error, Unmarshal
+ *	walks every tagged field and returns a single error listing every
+ *	missing-required path and every type mismatch, so callers can fix all of
+ *	them at once instead of one deploy at a time.
+ */
+func (jc *JuiceConfig) Unmarshal(target interface{}) error {
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return errors.New("Unmarshal target must be a pointer to a struct")
+	}
+	elem := v.Elem()
+	t := elem.Type()
+
+	var problems []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("juice")
+		if tag == "" {
+			continue
+		}
+		if field.PkgPath != "" {
+			problems = append(problems, field.Name+": juice tag on unexported field")
+			continue
+		}
+
+		parts := strings.Split(tag, ",")
+		path := parts[0]
+		required := false
+		defaultValue := ""
+		hasDefault := false
+		for _, opt := range parts[1:] {
+			if opt == "required" {
+				required = true
+			} else if strings.HasPrefix(opt, "default=") {
+				hasDefault = true
+				defaultValue = opt[len("default="):]
+			}
+		}
+
+		fieldValue := elem.Field(i)
+		jvalue, ok := jc.getValue(path)
+		if !ok {
+			if hasDefault {
+				if err := setFieldFromString(fieldValue, defaultValue); err != nil {
+					problems = append(problems, field.Name+" ("+path+"): invalid default ["+err.Error()+"]")
+				}
+			} else if required {
+				problems = append(problems, field.Name+" ("+path+"): required value not found")
+			}
+			continue
+		}
+
+		if err := setFieldFromJason(fieldValue, jvalue); err != nil {
+			problems = append(problems, field.Name+" ("+path+"): "+err.Error())
+		}
+	}
+
+	if len(problems) > 0 {
+		return errors.New("Unmarshal found " + strconv.Itoa(len(problems)) + " problem(s): " + strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+/*setFieldFromJason Set a struct field from a config value, per its Go kind.
+ */
+func setFieldFromJason(fieldValue reflect.Value, jvalue *jason.Value) error {
+	switch fieldValue.Kind() {
+	case reflect.String:
+		value, err := jvalue.String()
+		if err != nil {
+			return errors.New("value is not a string")
+		}
+		fieldValue.SetString(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value, err := jvalue.Int64()
+		if err != nil {
+			return errors.New("value is not an int64")
+		}
+		fieldValue.SetInt(value)
+	case reflect.Bool:
+		value, err := jvalue.Boolean()
+		if err != nil {
+			return errors.New("value is not a bool")
+		}
+		fieldValue.SetBool(value)
+	case reflect.Float32, reflect.Float64:
+		value, err := jvalue.Float64()
+		if err != nil {
+			return errors.New("value is not a float64")
+		}
+		fieldValue.SetFloat(value)
+	default:
+		return errors.New("unsupported field type [" + fieldValue.Kind().String() + "]")
+	}
+	return nil
+}
+
+/*setFieldFromString Set a struct field by parsing a raw string, used for `default=` tags.
+ */
+func setFieldFromString(fieldValue reflect.Value, raw string) error {
+	switch fieldValue.Kind() {
+	case reflect.String:
+		fieldValue.SetString(raw)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return err
+		}
+		fieldValue.SetInt(value)
+	case reflect.Bool:
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		fieldValue.SetBool(value)
+	case reflect.Float32, reflect.Float64:
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return err
+		}
+		fieldValue.SetFloat(value)
+	default:
+		return errors.New("unsupported field type [" + fieldValue.Kind().String() + "]")
+	}
+	return nil
 }
 
 /*
  *	Conveniance functions for simpler access.
- *	A default configuration file is defined using environment variable JUICE_CONFIG.
+ *	A default configuration file is defined using environment variable JUICE_CONFIG,
+ *	and lazily loaded on first use. Use Init or SetDefault to set it up explicitly
+ *	instead (e.g. from tests, or when the URL isn't coming from an env var).
  */
-var defaultConfig *JuiceConfig
+var (
+	defaultConfig     *JuiceConfig
+	defaultConfigOnce sync.Once
+	defaultConfigErr  error
+	defaultSession    *Session
+)
 
+/*checkDefaultConfigIsLoaded Lazily load the default config from JUICE_CONFIG, exactly once,
+ *even if called concurrently from multiple goroutines.
+ */
 func checkDefaultConfigIsLoaded() error {
+	defaultConfigOnce.Do(func() {
+		envvar := os.Getenv("JUICE_CONFIG")
+		fmt.Printf("JUICE_CONFIG=%s.\n", envvar)
 
-	// Is the default config already loaded?
-	if defaultConfig != nil {
-		return nil
-	}
-
-	// Get the config location from an environment variable
-	envvar := os.Getenv("JUICE_CONFIG")
-	fmt.Printf("JUICE_CONFIG=%s.\n", envvar)
+		jc, err := Load(envvar)
+		if err != nil {
+			fmt.Printf("Error loading default config: %+v\n", err)
+			defaultConfigErr = err
+			return
+		}
+		setDefault(jc)
+	})
+	return defaultConfigErr
+}
 
-	// Load the default config
-	var err error
-	defaultConfig, err = Load(envvar)
+/*Init Explicitly load and install the default config from a URL, instead of
+ *relying on the JUICE_CONFIG environment variable. Intended to be called
+ *once, e.g. from main(), before any package-level GetString/GetInt/GetBool
+ *calls.
+ */
+func Init(url string) error {
+	jc, err := Load(url)
 	if err != nil {
-		fmt.Printf("Error loading default config: %+v\n", err)
 		return err
 	}
+	SetDefault(jc)
 	return nil
 }
 
+/*SetDefault Install an already-loaded JuiceConfig as the default config. Intended for
+ *tests and other non-env-var-driven setups.
+ */
+func SetDefault(jc *JuiceConfig) {
+	setDefault(jc)
+	// Mark the lazy env-var initializer as already satisfied, so it doesn't
+	// later overwrite what was explicitly installed here.
+	defaultConfigOnce.Do(func() {})
+}
+
+/*setDefault Install jc as the default config and give it a fresh default Session.
+ */
+func setDefault(jc *JuiceConfig) {
+	defaultConfig = jc
+	defaultConfigErr = nil
+	defaultSession = jc.Session()
+}
+
 /*GetString Get a string value from the config defined by JUICE_CONFIG.
+ *
+ *	This uses a single Session shared by all package-level callers, so (as
+ *	before) it follows the "check WasError() at the end" pattern for
+ *	single-goroutine callers. Concurrent callers that need isolated error
+ *	state should keep their own JuiceConfig (e.g. the one passed to
+ *	SetDefault) and call its Session().
  */
 func GetString(path string, dflt ...string) (string, error) {
-	checkDefaultConfigIsLoaded()
-	return defaultConfig.GetString(path, dflt...)
+	if err := checkDefaultConfigIsLoaded(); err != nil {
+		return "", err
+	}
+	return defaultSession.GetString(path, dflt...)
 }
 
 /*GetInt Get an integer value from the config defined by JUICE_CONFIG.
  */
 func GetInt(path string, dflt ...int64) (int64, error) {
-	checkDefaultConfigIsLoaded()
-	return defaultConfig.GetInt(path, dflt...)
+	if err := checkDefaultConfigIsLoaded(); err != nil {
+		return 0, err
+	}
+	return defaultSession.GetInt(path, dflt...)
 }
 
 /*GetBool Get a boolean value from the config defined by JUICE_CONFIG.
  */
 func GetBool(path string, dflt ...bool) (bool, error) {
-	checkDefaultConfigIsLoaded()
-	return defaultConfig.GetBool(path, dflt...)
+	if err := checkDefaultConfigIsLoaded(); err != nil {
+		return false, err
+	}
+	return defaultSession.GetBool(path, dflt...)
+}
+
+/*GetFloat64 Get a float64 value from the config defined by JUICE_CONFIG.
+ */
+func GetFloat64(path string, dflt ...float64) (float64, error) {
+	if err := checkDefaultConfigIsLoaded(); err != nil {
+		return 0, err
+	}
+	return defaultSession.GetFloat64(path, dflt...)
+}
+
+/*GetDuration Get a time.Duration value from the config defined by JUICE_CONFIG.
+ */
+func GetDuration(path string, dflt ...time.Duration) (time.Duration, error) {
+	if err := checkDefaultConfigIsLoaded(); err != nil {
+		return 0, err
+	}
+	return defaultSession.GetDuration(path, dflt...)
+}
+
+/*GetStringSlice Get a string slice value from the config defined by JUICE_CONFIG.
+ */
+func GetStringSlice(path string, dflt ...[]string) ([]string, error) {
+	if err := checkDefaultConfigIsLoaded(); err != nil {
+		return nil, err
+	}
+	return defaultSession.GetStringSlice(path, dflt...)
 }
 
 /*WasError Has an error occurred?
@@ -298,17 +1414,17 @@ func GetBool(path string, dflt ...bool) (bool, error) {
  *	we can check at the end.
  */
 func WasError() bool {
-	return defaultConfig.WasError()
+	return defaultSession.WasError()
 }
 
 /*ErrorMessage Get the previous error's message
  */
 func ErrorMessage() string {
-	return defaultConfig.ErrorMessage()
+	return defaultSession.ErrorMessage()
 }
 
 /*ResetError Reset the error status.
  */
 func ResetError() {
-	defaultConfig.ResetError()
+	defaultSession.ResetError()
 }
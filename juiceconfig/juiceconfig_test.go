@@ -0,0 +1,391 @@
+package juiceconfig
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeTempConfig(t *testing.T, contents string) string {
+	t.Helper()
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "config.json")
+	if err := ioutil.WriteFile(filename, []byte(contents), 0644); err != nil {
+		t.Fatalf("Unable to write temp config: %+v", err)
+	}
+	return filename
+}
+
+func TestLoadMultipleSourcesPrecedence(t *testing.T) {
+	base := writeTempConfig(t, `{"app": {"name": "base", "size": 10}, "debug": false}`)
+	patch := writeTempConfig(t, `{"app": {"size": 20}}`)
+
+	jc, err := Load(FILE_PREFIX+base, FILE_PREFIX+patch)
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v", err)
+	}
+
+	name, err := jc.GetString("app.name")
+	if err != nil || name != "base" {
+		t.Errorf("Expected app.name=base, got %s (err %+v)", name, err)
+	}
+
+	size, err := jc.GetInt("app.size")
+	if err != nil || size != 20 {
+		t.Errorf("Expected app.size=20 (from later source), got %d (err %+v)", size, err)
+	}
+
+	debug, err := jc.GetBool("debug")
+	if err != nil || debug != false {
+		t.Errorf("Expected debug=false (from earlier source), got %v (err %+v)", debug, err)
+	}
+}
+
+func TestLoadYamlFile(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "config.yaml")
+	contents := "app:\n  name: myApp\n  size: 10\ndebug: true\n"
+	if err := ioutil.WriteFile(filename, []byte(contents), 0644); err != nil {
+		t.Fatalf("Unable to write temp config: %+v", err)
+	}
+
+	jc, err := Load(YAML_FILE_PREFIX + filename)
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v", err)
+	}
+
+	name, err := jc.GetString("app.name")
+	if err != nil || name != "myApp" {
+		t.Errorf("Expected app.name=myApp, got %s (err %+v)", name, err)
+	}
+
+	size, err := jc.GetInt("app.size")
+	if err != nil || size != 10 {
+		t.Errorf("Expected app.size=10, got %d (err %+v)", size, err)
+	}
+}
+
+func TestLoadDotenvFile(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "config.env")
+	contents := "# a comment\nAPP_NAME=myApp\nAPP_SIZE=10\n"
+	if err := ioutil.WriteFile(filename, []byte(contents), 0644); err != nil {
+		t.Fatalf("Unable to write temp config: %+v", err)
+	}
+
+	jc, err := Load(DOTENV_FILE_PREFIX + filename)
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v", err)
+	}
+
+	name, err := jc.GetString("APP_NAME")
+	if err != nil || name != "myApp" {
+		t.Errorf("Expected APP_NAME=myApp, got %s (err %+v)", name, err)
+	}
+}
+
+func TestAllowEnvOverrides(t *testing.T) {
+	base := writeTempConfig(t, `{"app": {"name": "base", "size": 10}}`)
+	jc, err := Load(FILE_PREFIX + base)
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v", err)
+	}
+
+	jc.AllowEnvOverrides("MYAPP")
+	os.Setenv("MYAPP_APP_NAME", "overridden")
+	defer os.Unsetenv("MYAPP_APP_NAME")
+
+	name, err := jc.GetString("app.name")
+	if err != nil || name != "overridden" {
+		t.Errorf("Expected app.name=overridden, got %s (err %+v)", name, err)
+	}
+
+	size, err := jc.GetInt("app.size")
+	if err != nil || size != 10 {
+		t.Errorf("Expected app.size=10 (no override set), got %d (err %+v)", size, err)
+	}
+
+	overrides := jc.GetEnvironmentOverrides()
+	if !overrides["app.name"] {
+		t.Errorf("Expected app.name to be reported as an environment override, got %+v", overrides)
+	}
+	if overrides["app.size"] {
+		t.Errorf("Did not expect app.size to be reported as an environment override, got %+v", overrides)
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	filename := writeTempConfig(t, `{"app": {"name": "myApp", "debug": true}}`)
+	jc, err := Load(FILE_PREFIX + filename)
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v", err)
+	}
+
+	var cfg struct {
+		Name  string `juice:"app.name,required"`
+		Size  int64  `juice:"app.size,default=10"`
+		Debug bool   `juice:"app.debug"`
+	}
+	if err := jc.Unmarshal(&cfg); err != nil {
+		t.Fatalf("Unexpected error from Unmarshal: %+v", err)
+	}
+	if cfg.Name != "myApp" {
+		t.Errorf("Expected Name=myApp, got %s", cfg.Name)
+	}
+	if cfg.Size != 10 {
+		t.Errorf("Expected Size=10 (from default), got %d", cfg.Size)
+	}
+	if !cfg.Debug {
+		t.Errorf("Expected Debug=true")
+	}
+}
+
+func TestUnmarshalAggregatesErrors(t *testing.T) {
+	filename := writeTempConfig(t, `{"app": {"name": "myApp", "size": "not-a-number"}}`)
+	jc, err := Load(FILE_PREFIX + filename)
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v", err)
+	}
+
+	var cfg struct {
+		Name    string `juice:"app.name,required"`
+		Size    int64  `juice:"app.size"`
+		Missing string `juice:"app.missing,required"`
+	}
+	err = jc.Unmarshal(&cfg)
+	if err == nil {
+		t.Fatal("Expected an error from Unmarshal")
+	}
+	if !strings.Contains(err.Error(), "app.size") || !strings.Contains(err.Error(), "app.missing") {
+		t.Errorf("Expected aggregated error to mention both problem paths, got: %s", err.Error())
+	}
+}
+
+func TestUnmarshalReportsUnexportedTaggedField(t *testing.T) {
+	filename := writeTempConfig(t, `{"app": {"name": "myApp"}}`)
+	jc, err := Load(FILE_PREFIX + filename)
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v", err)
+	}
+
+	var cfg struct {
+		Name string `juice:"app.name"`
+		name string `juice:"app.name"`
+	}
+	err = jc.Unmarshal(&cfg)
+	if err == nil {
+		t.Fatal("Expected an error from Unmarshal for an unexported tagged field")
+	}
+	if !strings.Contains(err.Error(), "unexported") {
+		t.Errorf("Expected error to mention the unexported field, got: %s", err.Error())
+	}
+	if cfg.Name != "myApp" {
+		t.Errorf("Expected the exported field to still be populated, got %s", cfg.Name)
+	}
+}
+
+func TestWatchReloadsOnFileChange(t *testing.T) {
+	filename := writeTempConfig(t, `{"app": {"name": "base"}}`)
+
+	jc, err := Load(FILE_PREFIX + filename)
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v", err)
+	}
+
+	changes := make(chan []string, 1)
+	stop, err := jc.Watch(50*time.Millisecond, func(jc *JuiceConfig, changedPaths []string) {
+		changes <- changedPaths
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error from Watch: %+v", err)
+	}
+	defer stop()
+
+	if err := ioutil.WriteFile(filename, []byte(`{"app": {"name": "updated"}}`), 0644); err != nil {
+		t.Fatalf("Unable to update temp config: %+v", err)
+	}
+
+	select {
+	case paths := <-changes:
+		if len(paths) == 0 {
+			t.Errorf("Expected at least one changed path, got none")
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("Timed out waiting for Watch to pick up the file change")
+	}
+
+	name, err := jc.GetString("app.name")
+	if err != nil || name != "updated" {
+		t.Errorf("Expected app.name=updated after reload, got %s (err %+v)", name, err)
+	}
+}
+
+func TestWatchReloadsOnAtomicRename(t *testing.T) {
+	filename := writeTempConfig(t, `{"app": {"name": "base"}}`)
+
+	jc, err := Load(FILE_PREFIX + filename)
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v", err)
+	}
+
+	changes := make(chan []string, 1)
+	stop, err := jc.Watch(50*time.Millisecond, func(jc *JuiceConfig, changedPaths []string) {
+		changes <- changedPaths
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error from Watch: %+v", err)
+	}
+	defer stop()
+
+	// Safe config writers (Consul Template, Vault Agent, ...) replace a file by writing to a temp path and renaming it
+	// over the target, rather than writing to the watched path directly.
+	tmp := filename + ".tmp"
+	if err := ioutil.WriteFile(tmp, []byte(`{"app": {"name": "updated"}}`), 0644); err != nil {
+		t.Fatalf("Unable to write replacement temp config: %+v", err)
+	}
+	if err := os.Rename(tmp, filename); err != nil {
+		t.Fatalf("Unable to rename replacement config into place: %+v", err)
+	}
+
+	select {
+	case paths := <-changes:
+		if len(paths) == 0 {
+			t.Errorf("Expected at least one changed path, got none")
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("Timed out waiting for Watch to pick up the atomic rename")
+	}
+
+	name, err := jc.GetString("app.name")
+	if err != nil || name != "updated" {
+		t.Errorf("Expected app.name=updated after reload, got %s (err %+v)", name, err)
+	}
+}
+
+func TestSessionHasIndependentStickyError(t *testing.T) {
+	filename := writeTempConfig(t, `{"app": {"name": "myApp"}}`)
+	jc, err := Load(FILE_PREFIX + filename)
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v", err)
+	}
+
+	sessionA := jc.Session()
+	sessionB := jc.Session()
+
+	if _, err := sessionA.GetString("app.missing"); err == nil {
+		t.Fatal("Expected an error for a missing path")
+	}
+	if !sessionA.WasError() {
+		t.Errorf("Expected sessionA.WasError() to be true")
+	}
+
+	// sessionB is unaffected by sessionA's sticky error.
+	name, err := sessionB.GetString("app.name")
+	if err != nil || name != "myApp" {
+		t.Errorf("Expected sessionB.GetString(app.name)=myApp, got %s (err %+v)", name, err)
+	}
+	if sessionB.WasError() {
+		t.Errorf("Did not expect sessionB.WasError() to be true")
+	}
+}
+
+func TestSetDefaultAndPackageLevelAccessors(t *testing.T) {
+	filename := writeTempConfig(t, `{"app": {"name": "myApp", "ratio": 0.5, "timeout": "30s", "hosts": "a,b"}}`)
+	jc, err := Load(FILE_PREFIX + filename)
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v", err)
+	}
+	SetDefault(jc)
+
+	name, err := GetString("app.name")
+	if err != nil || name != "myApp" {
+		t.Errorf("Expected GetString(app.name)=myApp, got %s (err %+v)", name, err)
+	}
+
+	ratio, err := GetFloat64("app.ratio")
+	if err != nil || ratio != 0.5 {
+		t.Errorf("Expected GetFloat64(app.ratio)=0.5, got %v (err %+v)", ratio, err)
+	}
+
+	timeout, err := GetDuration("app.timeout")
+	if err != nil || timeout != 30*time.Second {
+		t.Errorf("Expected GetDuration(app.timeout)=30s, got %v (err %+v)", timeout, err)
+	}
+
+	hosts, err := GetStringSlice("app.hosts")
+	if err != nil || len(hosts) != 2 || hosts[0] != "a" || hosts[1] != "b" {
+		t.Errorf("Expected GetStringSlice(app.hosts)=[a b], got %v (err %+v)", hosts, err)
+	}
+
+	if WasError() {
+		t.Errorf("Did not expect WasError() to be true")
+	}
+}
+
+func TestGetFloat64AndGetDuration(t *testing.T) {
+	filename := writeTempConfig(t, `{"app": {"ratio": 0.5, "timeout": "30s", "interval": 45}}`)
+	jc, err := Load(FILE_PREFIX + filename)
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v", err)
+	}
+
+	ratio, err := jc.GetFloat64("app.ratio")
+	if err != nil || ratio != 0.5 {
+		t.Errorf("Expected app.ratio=0.5, got %v (err %+v)", ratio, err)
+	}
+
+	timeout, err := jc.GetDuration("app.timeout")
+	if err != nil || timeout != 30*time.Second {
+		t.Errorf("Expected app.timeout=30s, got %v (err %+v)", timeout, err)
+	}
+
+	interval, err := jc.GetDuration("app.interval")
+	if err != nil || interval != 45*time.Second {
+		t.Errorf("Expected app.interval=45s (plain seconds), got %v (err %+v)", interval, err)
+	}
+}
+
+func TestGetStringSlice(t *testing.T) {
+	filename := writeTempConfig(t, `{"app": {"hosts": ["a", "b", "c"], "tags": "x,y, z"}}`)
+	jc, err := Load(FILE_PREFIX + filename)
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v", err)
+	}
+
+	hosts, err := jc.GetStringSlice("app.hosts")
+	if err != nil || len(hosts) != 3 || hosts[0] != "a" || hosts[2] != "c" {
+		t.Errorf("Expected app.hosts=[a b c], got %v (err %+v)", hosts, err)
+	}
+
+	tags, err := jc.GetStringSlice("app.tags")
+	if err != nil || len(tags) != 3 || tags[1] != "y" || tags[2] != "z" {
+		t.Errorf("Expected app.tags=[x y z] (split on commas), got %v (err %+v)", tags, err)
+	}
+}
+
+func TestGetSubConfig(t *testing.T) {
+	filename := writeTempConfig(t, `{"app": {"db": {"host": "localhost", "port": 5432}}, "other": "value"}`)
+	jc, err := Load(FILE_PREFIX + filename)
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v", err)
+	}
+
+	dbConfig := jc.GetSubConfig("app.db")
+
+	host, err := dbConfig.GetString("host")
+	if err != nil || host != "localhost" {
+		t.Errorf("Expected host=localhost, got %s (err %+v)", host, err)
+	}
+
+	port, err := dbConfig.GetInt("port")
+	if err != nil || port != 5432 {
+		t.Errorf("Expected port=5432, got %d (err %+v)", port, err)
+	}
+
+	if _, err := dbConfig.GetString("app.db.host"); err == nil {
+		t.Errorf("Expected the prefix to have been stripped, but full path still resolved")
+	}
+}